@@ -62,5 +62,179 @@ CREATE TABLE IF NOT EXISTS supplier (
 		return err
 	}
 
+	// The remaining nine tables are the standard TPC-C schema, reused
+	// as-is so CH-benCHmark's OLTP terminals run against the exact same
+	// tables its OLAP streams join against.
+	return w.createTpccTables(ctx)
+}
+
+// createTpccTables creates the TPC-C side of the CH-benCHmark schema
+// (warehouse/district/customer/history/orders/new_order/order_line/
+// stock/item), so createTables() is idempotent over the full 12-table set
+// described by allTables.
+func (w *Workloader) createTpccTables(ctx context.Context) error {
+	query := `
+CREATE TABLE IF NOT EXISTS warehouse (
+    W_ID BIGINT NOT NULL,
+    W_NAME VARCHAR(10) NULL,
+    W_STREET_1 VARCHAR(20) NULL,
+    W_STREET_2 VARCHAR(20) NULL,
+    W_CITY VARCHAR(20) NULL,
+    W_STATE CHAR(2) NULL,
+    W_ZIP CHAR(9) NULL,
+    W_TAX DECIMAL(4, 4) NULL,
+    W_YTD DECIMAL(12, 2) NULL,
+    PRIMARY KEY (W_ID)
+)`
+	if err := w.createTableDDL(ctx, query, "warehouse", "creating"); err != nil {
+		return err
+	}
+
+	query = `
+CREATE TABLE IF NOT EXISTS district (
+    D_ID BIGINT NOT NULL,
+    D_W_ID BIGINT NOT NULL,
+    D_NAME VARCHAR(10) NULL,
+    D_STREET_1 VARCHAR(20) NULL,
+    D_STREET_2 VARCHAR(20) NULL,
+    D_CITY VARCHAR(20) NULL,
+    D_STATE CHAR(2) NULL,
+    D_ZIP CHAR(9) NULL,
+    D_TAX DECIMAL(4, 4) NULL,
+    D_YTD DECIMAL(12, 2) NULL,
+    D_NEXT_O_ID BIGINT NULL,
+    PRIMARY KEY (D_W_ID, D_ID)
+)`
+	if err := w.createTableDDL(ctx, query, "district", "creating"); err != nil {
+		return err
+	}
+
+	query = `
+CREATE TABLE IF NOT EXISTS customer (
+    C_ID BIGINT NOT NULL,
+    C_D_ID BIGINT NOT NULL,
+    C_W_ID BIGINT NOT NULL,
+    C_FIRST VARCHAR(16) NULL,
+    C_MIDDLE CHAR(2) NULL,
+    C_LAST VARCHAR(16) NULL,
+    C_STREET_1 VARCHAR(20) NULL,
+    C_STREET_2 VARCHAR(20) NULL,
+    C_CITY VARCHAR(20) NULL,
+    C_STATE CHAR(2) NULL,
+    C_ZIP CHAR(9) NULL,
+    C_PHONE CHAR(16) NULL,
+    C_SINCE TIMESTAMP NULL,
+    C_CREDIT CHAR(2) NULL,
+    C_CREDIT_LIM DECIMAL(12, 2) NULL,
+    C_DISCOUNT DECIMAL(4, 4) NULL,
+    C_BALANCE DECIMAL(12, 2) NULL,
+    C_YTD_PAYMENT DECIMAL(12, 2) NULL,
+    C_PAYMENT_CNT BIGINT NULL,
+    C_DELIVERY_CNT BIGINT NULL,
+    C_DATA VARCHAR(500) NULL,
+    PRIMARY KEY (C_W_ID, C_D_ID, C_ID)
+)`
+	if err := w.createTableDDL(ctx, query, "customer", "creating"); err != nil {
+		return err
+	}
+
+	query = `
+CREATE TABLE IF NOT EXISTS history (
+    H_C_ID BIGINT NULL,
+    H_C_D_ID BIGINT NULL,
+    H_C_W_ID BIGINT NULL,
+    H_D_ID BIGINT NULL,
+    H_W_ID BIGINT NULL,
+    H_DATE TIMESTAMP NULL,
+    H_AMOUNT DECIMAL(6, 2) NULL,
+    H_DATA VARCHAR(24) NULL
+)`
+	if err := w.createTableDDL(ctx, query, "history", "creating"); err != nil {
+		return err
+	}
+
+	query = `
+CREATE TABLE IF NOT EXISTS orders (
+    O_ID BIGINT NOT NULL,
+    O_D_ID BIGINT NOT NULL,
+    O_W_ID BIGINT NOT NULL,
+    O_C_ID BIGINT NULL,
+    O_ENTRY_D TIMESTAMP NULL,
+    O_CARRIER_ID BIGINT NULL,
+    O_OL_CNT BIGINT NULL,
+    O_ALL_LOCAL BIGINT NULL,
+    PRIMARY KEY (O_W_ID, O_D_ID, O_ID)
+)`
+	if err := w.createTableDDL(ctx, query, "orders", "creating"); err != nil {
+		return err
+	}
+
+	query = `
+CREATE TABLE IF NOT EXISTS new_order (
+    NO_O_ID BIGINT NOT NULL,
+    NO_D_ID BIGINT NOT NULL,
+    NO_W_ID BIGINT NOT NULL,
+    PRIMARY KEY (NO_W_ID, NO_D_ID, NO_O_ID)
+)`
+	if err := w.createTableDDL(ctx, query, "new_order", "creating"); err != nil {
+		return err
+	}
+
+	query = `
+CREATE TABLE IF NOT EXISTS order_line (
+    OL_O_ID BIGINT NOT NULL,
+    OL_D_ID BIGINT NOT NULL,
+    OL_W_ID BIGINT NOT NULL,
+    OL_NUMBER BIGINT NOT NULL,
+    OL_I_ID BIGINT NULL,
+    OL_SUPPLY_W_ID BIGINT NULL,
+    OL_DELIVERY_D TIMESTAMP NULL,
+    OL_QUANTITY BIGINT NULL,
+    OL_AMOUNT DECIMAL(6, 2) NULL,
+    OL_DIST_INFO CHAR(24) NULL,
+    PRIMARY KEY (OL_W_ID, OL_D_ID, OL_O_ID, OL_NUMBER)
+)`
+	if err := w.createTableDDL(ctx, query, "order_line", "creating"); err != nil {
+		return err
+	}
+
+	query = `
+CREATE TABLE IF NOT EXISTS item (
+    I_ID BIGINT NOT NULL,
+    I_IM_ID BIGINT NULL,
+    I_NAME VARCHAR(24) NULL,
+    I_PRICE DECIMAL(5, 2) NULL,
+    I_DATA VARCHAR(50) NULL,
+    PRIMARY KEY (I_ID)
+)`
+	if err := w.createTableDDL(ctx, query, "item", "creating"); err != nil {
+		return err
+	}
+
+	query = `
+CREATE TABLE IF NOT EXISTS stock (
+    S_I_ID BIGINT NOT NULL,
+    S_W_ID BIGINT NOT NULL,
+    S_QUANTITY BIGINT NULL,
+    S_DIST_01 CHAR(24) NULL,
+    S_DIST_02 CHAR(24) NULL,
+    S_DIST_03 CHAR(24) NULL,
+    S_DIST_04 CHAR(24) NULL,
+    S_DIST_05 CHAR(24) NULL,
+    S_DIST_06 CHAR(24) NULL,
+    S_DIST_07 CHAR(24) NULL,
+    S_DIST_08 CHAR(24) NULL,
+    S_DIST_09 CHAR(24) NULL,
+    S_DIST_10 CHAR(24) NULL,
+    S_YTD BIGINT NULL,
+    S_ORDER_CNT BIGINT NULL,
+    S_REMOTE_CNT BIGINT NULL,
+    S_DATA VARCHAR(50) NULL,
+    PRIMARY KEY (S_W_ID, S_I_ID)
+)`
+	if err := w.createTableDDL(ctx, query, "stock", "creating"); err != nil {
+		return err
+	}
+
 	return nil
 }
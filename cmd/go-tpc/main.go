@@ -6,17 +6,24 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/pingcap/go-tpc/pkg/db"
+	"github.com/pingcap/go-tpc/pkg/metrics"
 	"github.com/pingcap/go-tpc/pkg/util"
 	"github.com/spf13/cobra"
 
 	// mysql package
 	_ "github.com/go-sql-driver/mysql"
-	// pg
+	// pg, and cockroachdb which speaks the same wire protocol
 	_ "github.com/lib/pq"
+	// sqlite3
+	_ "github.com/mattn/go-sqlite3"
+	// mssql
+	_ "github.com/denisenkom/go-mssqldb"
 )
 
 var (
@@ -41,16 +48,35 @@ var (
 	maxProcs       int
 	connParams     string
 	outputStyle    string
+	maxQPS         float64
+	targetTPMC     int
+	maxRetries     int
+	reportFile     string
+	reportFormat   string
+	olapMaxQPS     float64
+	metricsBuckets string
 
 	globalDB  *sql.DB
 	globalCtx context.Context
 )
 
-const (
-	createDBDDL = "CREATE DATABASE "
-	mysqlDriver = "mysql"
-	pgDriver    = "postgres"
-)
+const mysqlDriver = "mysql"
+
+// parseMetricsBuckets parses --metrics-buckets, a comma-separated list of
+// latency bucket boundaries in seconds (e.g. "0.001,0.01,0.1,1"), into the
+// float64 slice Prometheus histograms expect.
+func parseMetricsBuckets(s string) ([]float64, error) {
+	fields := strings.Split(s, ",")
+	buckets := make([]float64, 0, len(fields))
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --metrics-buckets value %q: %v", f, err)
+		}
+		buckets = append(buckets, v)
+	}
+	return buckets, nil
+}
 
 func closeDB() {
 	if globalDB != nil {
@@ -59,44 +85,33 @@ func closeDB() {
 	globalDB = nil
 }
 
-func buildDSN(tmp bool) string {
-	switch driver {
-	case mysqlDriver:
-		if tmp {
-			return fmt.Sprintf("%s:%s@tcp(%s:%d)/", user, password, host, port)
-		}
-		// allow multiple statements in one query to allow q15 on the TPC-H
-		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?multiStatements=true", user, password, host, port, dbName)
-		if len(connParams) > 0 {
-			dsn = dsn + "&" + connParams
-		}
-		return dsn
-	case pgDriver:
-		if tmp {
-			return fmt.Sprintf("postgres://%s:%s@%s:%d/?%s", user, password, host, port, connParams)
-		}
-		dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s", user, password, host, port, dbName)
-		if len(connParams) > 0 {
-			dsn = dsn + "?" + connParams
-		}
-		return dsn
-	default:
-		panic(fmt.Errorf("unknown driver: %q", driver))
+// dbDriver looks up the registered pkg/db.Driver for --driver, panicking
+// with the list of registered names when --driver is unknown.
+func dbDriver() db.Driver {
+	d, ok := db.Get(driver)
+	if !ok {
+		panic(fmt.Errorf("unknown driver: %q, registered drivers are %v", driver, db.Names()))
 	}
+	return d
 }
 
-func isDBNotExist(err error) bool {
-	if err == nil {
-		return false
-	}
-	switch driver {
-	case mysqlDriver:
-		return strings.Contains(err.Error(), "Unknown database")
-	case pgDriver:
-		msg := err.Error()
-		return strings.HasPrefix(msg, "pq: database") && strings.HasSuffix(msg, "does not exist")
+func dbConfig() db.Config {
+	return db.Config{
+		Host:       host,
+		Port:       port,
+		User:       user,
+		Password:   password,
+		DBName:     dbName,
+		ConnParams: connParams,
 	}
-	return false
+}
+
+func buildDSN(tmp bool) string {
+	return dbDriver().DSN(dbConfig(), tmp)
+}
+
+func isDBNotExist(err error) bool {
+	return dbDriver().IsDBNotExist(err)
 }
 
 func openDB() {
@@ -104,15 +119,28 @@ func openDB() {
 		tmpDB *sql.DB
 		err   error
 	)
-	globalDB, err = sql.Open(driver, buildDSN(false))
+	d := dbDriver()
+	// cockroachdb has no database/sql driver of its own; it goes through lib/pq.
+	sqlDriver := driver
+	if driver == "cockroachdb" {
+		sqlDriver = "postgres"
+	}
+
+	globalDB, err = sql.Open(sqlDriver, buildDSN(false))
 	if err != nil {
 		panic(err)
 	}
 	if err := globalDB.Ping(); err != nil {
 		if isDBNotExist(err) {
-			tmpDB, _ = sql.Open(driver, buildDSN(true))
+			stmt := d.CreateDBStmt(dbName)
+			if len(stmt) == 0 {
+				// this engine expects the database to already exist
+				// (e.g. CockroachDB) and won't create it for us.
+				panic(fmt.Errorf("database %q does not exist and driver %q cannot create it", dbName, driver))
+			}
+			tmpDB, _ = sql.Open(sqlDriver, buildDSN(true))
 			defer tmpDB.Close()
-			if _, err := tmpDB.Exec(createDBDDL + dbName); err != nil {
+			if _, err := tmpDB.Exec(stmt); err != nil {
 				panic(fmt.Errorf("failed to create database, err %v\n", err))
 			}
 		} else {
@@ -120,6 +148,9 @@ func openDB() {
 		}
 	} else {
 		globalDB.SetMaxIdleConns(threads + acThreads + 1)
+		if err := d.InitSession(globalDB); err != nil {
+			panic(fmt.Errorf("failed to init session, err %v\n", err))
+		}
 	}
 }
 
@@ -139,7 +170,7 @@ func main() {
 	rootCmd.PersistentFlags().IntVarP(&statusPort, "statusPort", "S", 10080, "Database status port")
 	rootCmd.PersistentFlags().IntVarP(&threads, "threads", "T", 1, "Thread concurrency")
 	rootCmd.PersistentFlags().IntVarP(&acThreads, "acThreads", "t", 1, "OLAP client concurrency, only for CH-benCHmark")
-	rootCmd.PersistentFlags().StringVarP(&driver, "driver", "d", mysqlDriver, "Database driver: mysql, postgres")
+	rootCmd.PersistentFlags().StringVarP(&driver, "driver", "d", mysqlDriver, "Database driver: mysql, postgres, cockroachdb, sqlite3, mssql")
 	rootCmd.PersistentFlags().DurationVar(&totalTime, "time", 1<<63-1, "Total execution time")
 	rootCmd.PersistentFlags().IntVar(&totalCount, "count", 0, "Total execution count, 0 means infinite")
 	rootCmd.PersistentFlags().BoolVar(&dropData, "dropdata", false, "Cleanup data before prepare")
@@ -151,6 +182,26 @@ func main() {
 5: Snapshot, 6: Serializable, 7: Linerizable`)
 	rootCmd.PersistentFlags().StringVar(&connParams, "conn-params", "", "session variables, e.g. for TiDB --conn-params tidb_isolation_read_engines='tiflash', For PostgreSQL: --conn-params sslmode=disable")
 	rootCmd.PersistentFlags().StringVar(&outputStyle, "output", util.OutputStylePlain, "output style, valid values can be { plain | table | json }")
+	rootCmd.PersistentFlags().Float64Var(&maxQPS, "max-qps", 0, "Max queries per second over all threads, 0 means unlimited")
+	rootCmd.PersistentFlags().IntVar(&targetTPMC, "target-tpmc", 0, "Target tpmC to throttle TPC-C NewOrder transactions to, 0 means unlimited, overrides --max-qps")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", 0, "Max retries of a transaction that fails with a retryable (serialization conflict) error, 0 means don't retry")
+	rootCmd.PersistentFlags().StringVar(&reportFile, "report-file", "", "Write a post-run summary report (counts, tpmC/TPS, latency percentiles, errors by code) to this file, empty means don't write one")
+	rootCmd.PersistentFlags().StringVar(&reportFormat, "report-format", "json", "Format of --report-file, valid values can be { json | csv | toml }")
+	rootCmd.PersistentFlags().Float64Var(&olapMaxQPS, "olap-max-qps", 0, "Max queries per second over all OLAP streams, only for CH-benCHmark, 0 means unlimited")
+	rootCmd.PersistentFlags().StringVar(&metricsBuckets, "metrics-buckets", "", "Comma-separated Prometheus histogram bucket boundaries in seconds, e.g. --metrics-buckets=0.001,0.01,0.1,1; empty uses the built-in defaults")
+
+	// --port's literal default above is mysqlDriver's port. If the user
+	// picked a different --driver and didn't also pass --port, reseed it
+	// from that driver's DefaultPort() instead of silently connecting to
+	// the wrong port.
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if cmd.Flags().Changed("port") {
+			return
+		}
+		if d, ok := db.Get(driver); ok {
+			port = d.DefaultPort()
+		}
+	}
 
 	cobra.EnablePrefixMatching = true
 
@@ -163,6 +214,22 @@ func main() {
 	var cancel context.CancelFunc
 	globalCtx, cancel = context.WithCancel(context.Background())
 
+	// flags are only parsed inside rootCmd.Execute, so read them from an
+	// initializer, which cobra runs after parsing.
+	cobra.OnInitialize(func() {
+		if len(metricsBuckets) > 0 {
+			buckets, err := parseMetricsBuckets(metricsBuckets)
+			if err != nil {
+				panic(err)
+			}
+			metrics.SetBuckets(buckets)
+		}
+
+		if len(metricsAddr) > 0 {
+			metrics.Start(globalCtx, metricsAddr)
+		}
+	})
+
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc,
 		syscall.SIGHUP,
@@ -6,11 +6,139 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pingcap/go-tpc/pkg/metrics"
+	"github.com/pingcap/go-tpc/pkg/workload"
 	"github.com/siddontang/go-tpc/pkg/measurement"
-	"github.com/siddontang/go-tpc/pkg/workload"
+	"golang.org/x/time/rate"
 )
 
-func execute(ctx context.Context, w workload.Workloader, action string, index int) error {
+// dbLabel reports the workload-kind label a Run call should be recorded
+// under, so metrics from different benchmarks don't collide.
+func dbLabel(w workload.Workloader) string {
+	if namer, ok := w.(metrics.Namer); ok {
+		return namer.Name()
+	}
+	return "workload"
+}
+
+// opName reports the operation label a Run call should be recorded under.
+// Workloads that implement metrics.OpNamer get per-transaction granularity
+// (e.g. TPC-C's NewOrder/Payment); everything else is just labeled "run".
+func opName(ctx context.Context, w workload.Workloader, index int) string {
+	if namer, ok := w.(metrics.OpNamer); ok {
+		return namer.OpName(ctx, index)
+	}
+	return "run"
+}
+
+// warehouseID reports the warehouse label a Run call should be recorded
+// under, for workloads partitioned by warehouse (TPC-C). Workloads that
+// don't implement metrics.WarehouseNamer get an empty label.
+func warehouseID(ctx context.Context, w workload.Workloader, index int) string {
+	if namer, ok := w.(metrics.WarehouseNamer); ok {
+		return namer.WarehouseID(ctx, index)
+	}
+	return ""
+}
+
+// tpccNewOrderOp is the op label TPC-C's OpNamer reports for its New-Order
+// transaction, the one tpmC is conventionally measured against.
+const tpccNewOrderOp = "NewOrder"
+
+// rateLimiter pairs a token-bucket throttle with the op name it gates.
+// --target-tpmc is specifically a New-Order rate, and w.Run mixes
+// NewOrder/Payment/Delivery/OrderStatus/StockLevel, so throttling every
+// call to targetTPMC/60 qps would hold New-Order well under the configured
+// target; gatedOp restricts the wait to just the calls tpmC is measuring.
+// An empty gatedOp (--max-qps) throttles every call, regardless of op.
+type rateLimiter struct {
+	limiter *rate.Limiter
+	gatedOp string
+}
+
+// wait blocks for a token if l throttles op, and is a no-op otherwise
+// (including when l itself is nil, i.e. the run is open-loop).
+func (l *rateLimiter) wait(ctx context.Context, op string) error {
+	if l == nil || (l.gatedOp != "" && op != l.gatedOp) {
+		return nil
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// newRateLimiter builds the shared token-bucket throttle for --max-qps /
+// --target-tpmc, or nil when the run should stay open-loop. --target-tpmc
+// takes priority over --max-qps and gates only New-Order calls; tpmC is
+// expressed per-minute, so it's converted down to a per-second rate.
+func newRateLimiter() *rateLimiter {
+	if targetTPMC > 0 {
+		return &rateLimiter{limiter: rate.NewLimiter(rate.Limit(float64(targetTPMC)/60), 1), gatedOp: tpccNewOrderOp}
+	}
+	if maxQPS > 0 {
+		return &rateLimiter{limiter: rate.NewLimiter(rate.Limit(maxQPS), 1)}
+	}
+	return nil
+}
+
+// newOLAPRateLimiter builds the token-bucket throttle for the CH-benCHmark
+// analytical stream, independent of the OLTP stream's --max-qps so the two
+// can be tuned separately (analytical queries are long and few, OLTP
+// terminals are short and many).
+func newOLAPRateLimiter() *rate.Limiter {
+	if olapMaxQPS <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(olapMaxQPS), 1)
+}
+
+// executeOLAP drives one CH-benCHmark analytical stream, cycling through
+// the benchmark's queries via RunOLAP the same way execute drives an OLTP
+// terminal through Run. Metrics are recorded under a "-olap" suffixed db
+// label so OLTP and OLAP throughput/latency never share a bucket.
+func executeOLAP(ctx context.Context, w workload.OLAPRunner, index int, limiter *rate.Limiter) error {
+	count := totalCount / acThreads
+
+	ctx = w.InitThread(ctx, index)
+	defer w.CleanupThread(ctx, index)
+
+	for i := 0; totalCount == 0 || i < count; i++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil
+			}
+		}
+
+		db, op := dbLabel(w)+"-olap", opName(ctx, w, index)
+		err := metrics.Wrap(ctx, db, op, index, "", w, func() error {
+			return w.RunOLAP(ctx, index)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if ignoreError {
+				fmt.Printf("execute olap failed, err %v\n", err)
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// timePhase records how long a prepare/cleanup call took for the
+// --report-file summary, which reports prepare and run timing separately.
+func timePhase(db, phase string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	metrics.RecordPhase(db, phase, time.Since(start), err)
+	return err
+}
+
+func execute(ctx context.Context, w workload.Workloader, action string, index int, limiter *rateLimiter) error {
 	count := totalCount / threads
 
 	ctx = w.InitThread(ctx, index)
@@ -18,18 +146,41 @@ func execute(ctx context.Context, w workload.Workloader, action string, index in
 
 	switch action {
 	case "prepare":
-		if dropData {
-			if err := w.Cleanup(ctx, index); err != nil {
-				return err
+		return timePhase(dbLabel(w), "prepare", func() error {
+			if dropData {
+				if err := w.Cleanup(ctx, index); err != nil {
+					return err
+				}
 			}
-		}
-		return w.Prepare(ctx, index)
+			return w.Prepare(ctx, index)
+		})
 	case "cleanup":
-		return w.Cleanup(ctx, index)
+		return timePhase(dbLabel(w), "cleanup", func() error {
+			return w.Cleanup(ctx, index)
+		})
 	}
 
-	for i := 0; i < count; i++ {
-		if err := w.Run(ctx, index); err != nil {
+	// totalCount == 0 means the caller only bounded the run by --time, so
+	// loop until the context (carrying the --time deadline) is canceled.
+	for i := 0; totalCount == 0 || i < count; i++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		db, op := dbLabel(w), opName(ctx, w, index)
+		if err := limiter.wait(ctx, op); err != nil {
+			return nil
+		}
+
+		err := metrics.Wrap(ctx, db, op, index, warehouseID(ctx, w, index), w, func() error {
+			return workload.RunInNewTxn(ctx, driver, db, op, maxRetries, func() error {
+				return w.Run(ctx, index)
+			})
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
 			if ignoreError {
 				fmt.Printf("execute %s failed, err %v\n", action, err)
 				continue
@@ -42,9 +193,26 @@ func execute(ctx context.Context, w workload.Workloader, action string, index in
 }
 
 func executeWorkload(ctx context.Context, w workload.Workloader, action string) {
+	if totalTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, totalTime)
+		defer cancel()
+	}
+
+	limiter := newRateLimiter()
+
+	// CH-benCHmark mixes threads OLTP terminals with a separate, smaller
+	// pool of acThreads analytical streams; every other workload just gets
+	// its threads OLTP goroutines.
+	olapRunner, isOLAP := w.(workload.OLAPRunner)
+	runOLAP := isOLAP && action == "run"
+
 	var wg sync.WaitGroup
 
 	wg.Add(threads)
+	if runOLAP {
+		wg.Add(acThreads)
+	}
 
 	outputCtx, outputCancel := context.WithCancel(ctx)
 	ch := make(chan struct{}, 1)
@@ -66,13 +234,26 @@ func executeWorkload(ctx context.Context, w workload.Workloader, action string)
 	for i := 0; i < threads; i++ {
 		go func(index int) {
 			defer wg.Done()
-			if err := execute(ctx, w, action, index); err != nil {
+			if err := execute(ctx, w, action, index, limiter); err != nil {
 				fmt.Printf("execute %s failed, err %v\n", action, err)
 				return
 			}
 		}(i)
 	}
 
+	if runOLAP {
+		olapLimiter := newOLAPRateLimiter()
+		for i := 0; i < acThreads; i++ {
+			go func(index int) {
+				defer wg.Done()
+				if err := executeOLAP(ctx, olapRunner, index, olapLimiter); err != nil {
+					fmt.Printf("execute olap failed, err %v\n", err)
+					return
+				}
+			}(i)
+		}
+	}
+
 	wg.Wait()
 	outputCancel()
 
@@ -80,4 +261,10 @@ func executeWorkload(ctx context.Context, w workload.Workloader, action string)
 
 	fmt.Printf("Finished\n")
 	measurement.Output()
-}
\ No newline at end of file
+
+	if len(reportFile) > 0 {
+		if err := metrics.WriteReport(reportFile, reportFormat); err != nil {
+			fmt.Printf("failed to write report to %s, err %v\n", reportFile, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// cockroachdbDriver speaks the Postgres wire protocol, so it reuses
+// postgresDriver's error classification, but defaults to sslmode=disable
+// (CockroachDB's insecure mode doesn't speak TLS) and never issues a
+// CREATE DATABASE IF NOT EXISTS, since operators are expected to create
+// the database up front.
+type cockroachdbDriver struct {
+	postgresDriver
+}
+
+func init() {
+	Register("cockroachdb", cockroachdbDriver{})
+}
+
+func (cockroachdbDriver) DSN(cfg Config, tmp bool) string {
+	connParams := cfg.ConnParams
+	if len(connParams) == 0 {
+		connParams = "sslmode=disable"
+	}
+	cfg.ConnParams = connParams
+
+	if tmp {
+		return fmt.Sprintf("postgres://%s:%s@%s:%d/?%s", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.ConnParams)
+	}
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?%s", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName, cfg.ConnParams)
+}
+
+func (cockroachdbDriver) CreateDBStmt(name string) string {
+	return ""
+}
+
+func (cockroachdbDriver) DefaultPort() int {
+	return 26257
+}
+
+func (cockroachdbDriver) InitSession(conn *sql.DB) error {
+	return nil
+}
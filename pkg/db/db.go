@@ -0,0 +1,75 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Config carries the connection parameters a Driver needs to build a DSN
+// or session-init statements. It mirrors the persistent flags in
+// cmd/go-tpc/main.go.
+type Config struct {
+	Host       string
+	Port       int
+	User       string
+	Password   string
+	DBName     string
+	ConnParams string
+}
+
+// Driver adapts a database/sql driver to go-tpc's connection lifecycle:
+// building a DSN (with and without a target database, for the "does the
+// database exist yet" dance), recognizing a missing-database error,
+// generating the statement to create it, and any per-connection session
+// setup the engine needs.
+type Driver interface {
+	// DSN returns the data source name for cfg. When tmp is true, the
+	// returned DSN must not reference cfg.DBName, so callers can connect
+	// to run CreateDBStmt before the target database exists. main.go only
+	// asks for a tmp DSN when IsDBNotExist reports true and CreateDBStmt is
+	// non-empty, so drivers for which that never happens (sqlite3) ignore tmp.
+	DSN(cfg Config, tmp bool) string
+	// IsDBNotExist reports whether err is this engine's "database does not
+	// exist" error, as returned by the first Ping after DSN(cfg, false).
+	IsDBNotExist(err error) bool
+	// CreateDBStmt returns the statement used to create name, or "" if
+	// go-tpc doesn't create the database for this engine (e.g. SQLite
+	// creates the file on open; CockroachDB databases must exist up front).
+	CreateDBStmt(name string) string
+	// DefaultPort is the engine's conventional listener port. main.go
+	// resolves this once --driver is known and uses it to seed --port's
+	// default when the user didn't pass --port explicitly.
+	DefaultPort() int
+	// InitSession runs any per-connection setup the engine needs beyond
+	// the DSN (e.g. session variables that aren't valid as DSN params).
+	InitSession(conn *sql.DB) error
+}
+
+var registry = map[string]Driver{}
+
+// Register adds d to the driver registry under name, so it can be selected
+// with --driver. It's meant to be called from an init() in the package that
+// implements Driver, the same way database/sql drivers register
+// themselves.
+func Register(name string, d Driver) {
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("db: driver %q already registered", name))
+	}
+	registry[name] = d
+}
+
+// Get looks up a registered Driver by its --driver name.
+func Get(name string) (Driver, bool) {
+	d, ok := registry[name]
+	return d, ok
+}
+
+// Names returns the registered driver names, in no particular order, for
+// the unknown --driver error message.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
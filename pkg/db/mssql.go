@@ -0,0 +1,45 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// mssqlDriver targets SQL Server's sqlserver:// DSN scheme, which quotes
+// identifiers with square brackets rather than backticks or double quotes.
+type mssqlDriver struct{}
+
+func init() {
+	Register("mssql", mssqlDriver{})
+}
+
+func (mssqlDriver) DSN(cfg Config, tmp bool) string {
+	if tmp {
+		return fmt.Sprintf("sqlserver://%s:%s@%s:%d", cfg.User, cfg.Password, cfg.Host, cfg.Port)
+	}
+	dsn := fmt.Sprintf("sqlserver://%s:%s@%s:%d?database=%s", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName)
+	if len(cfg.ConnParams) > 0 {
+		dsn = dsn + "&" + cfg.ConnParams
+	}
+	return dsn
+}
+
+func (mssqlDriver) IsDBNotExist(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), "Cannot open database")
+}
+
+func (mssqlDriver) CreateDBStmt(name string) string {
+	return fmt.Sprintf("CREATE DATABASE [%s]", name)
+}
+
+func (mssqlDriver) DefaultPort() int {
+	return 1433
+}
+
+func (mssqlDriver) InitSession(conn *sql.DB) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type mysqlDriver struct{}
+
+func init() {
+	Register("mysql", mysqlDriver{})
+}
+
+func (mysqlDriver) DSN(cfg Config, tmp bool) string {
+	if tmp {
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/", cfg.User, cfg.Password, cfg.Host, cfg.Port)
+	}
+	// allow multiple statements in one query to allow q15 on the TPC-H
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?multiStatements=true", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName)
+	if len(cfg.ConnParams) > 0 {
+		dsn = dsn + "&" + cfg.ConnParams
+	}
+	return dsn
+}
+
+func (mysqlDriver) IsDBNotExist(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), "Unknown database")
+}
+
+func (mysqlDriver) CreateDBStmt(name string) string {
+	return "CREATE DATABASE " + name
+}
+
+func (mysqlDriver) DefaultPort() int {
+	return 4000
+}
+
+func (mysqlDriver) InitSession(conn *sql.DB) error {
+	return nil
+}
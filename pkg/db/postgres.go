@@ -0,0 +1,44 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type postgresDriver struct{}
+
+func init() {
+	Register("postgres", postgresDriver{})
+}
+
+func (postgresDriver) DSN(cfg Config, tmp bool) string {
+	if tmp {
+		return fmt.Sprintf("postgres://%s:%s@%s:%d/?%s", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.ConnParams)
+	}
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName)
+	if len(cfg.ConnParams) > 0 {
+		dsn = dsn + "?" + cfg.ConnParams
+	}
+	return dsn
+}
+
+func (postgresDriver) IsDBNotExist(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.HasPrefix(msg, "pq: database") && strings.HasSuffix(msg, "does not exist")
+}
+
+func (postgresDriver) CreateDBStmt(name string) string {
+	return "CREATE DATABASE " + name
+}
+
+func (postgresDriver) DefaultPort() int {
+	return 5432
+}
+
+func (postgresDriver) InitSession(conn *sql.DB) error {
+	return nil
+}
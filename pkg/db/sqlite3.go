@@ -0,0 +1,35 @@
+package db
+
+import "database/sql"
+
+// sqlite3Driver treats cfg.DBName as a file path. SQLite creates the file
+// on open, so there's no create-database dance and no "does not exist"
+// error class to recognize.
+type sqlite3Driver struct{}
+
+func init() {
+	Register("sqlite3", sqlite3Driver{})
+}
+
+func (sqlite3Driver) DSN(cfg Config, tmp bool) string {
+	return cfg.DBName
+}
+
+func (sqlite3Driver) IsDBNotExist(err error) bool {
+	return false
+}
+
+func (sqlite3Driver) CreateDBStmt(name string) string {
+	return ""
+}
+
+func (sqlite3Driver) DefaultPort() int {
+	return 0
+}
+
+func (sqlite3Driver) InitSession(conn *sql.DB) error {
+	// SQLite only supports a single writer; serialize access rather than
+	// let concurrent workers hit "database is locked".
+	conn.SetMaxOpenConns(1)
+	return nil
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// defaultExecBuckets covers sub-millisecond transactions up to
+// multi-second analytical queries, matching the latency spread seen across
+// TPC-C transactions and TPC-H/CH-benCHmark queries. --metrics-buckets
+// overrides this for workloads with a narrower or wider latency profile.
+var defaultExecBuckets = []float64{
+	.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60,
+}
+
+var (
+	execTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go_tpc",
+		Name:      "exec_total",
+		Help:      "Total number of workload operations processed, by workload, operation, thread and result.",
+	}, []string{"db", "op", "thread", "warehouse", "result"})
+
+	retryTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go_tpc",
+		Name:      "retry_total",
+		Help:      "Total number of transaction retries after a retryable error, by workload and operation.",
+	}, []string{"db", "op"})
+
+	execBuckets      = defaultExecBuckets
+	execDuration     *prometheus.HistogramVec
+	execDurationOnce sync.Once
+)
+
+func init() {
+	prometheus.MustRegister(execTotal, retryTotal)
+}
+
+// SetBuckets overrides the histogram buckets (in seconds) used by the
+// exec_duration_seconds histogram, e.g. from --metrics-buckets. It must be
+// called before the first Wrap call, since the histogram is registered
+// with Prometheus on first use.
+func SetBuckets(buckets []float64) {
+	execBuckets = buckets
+}
+
+// duration lazily builds and registers the exec_duration_seconds
+// histogram, so --metrics-buckets can still change execBuckets up until
+// the first recorded sample.
+func duration() *prometheus.HistogramVec {
+	execDurationOnce.Do(func() {
+		execDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "go_tpc",
+			Name:      "exec_duration_seconds",
+			Help:      "Latency distribution of workload operations, by workload, operation and thread.",
+			Buckets:   execBuckets,
+		}, []string{"db", "op", "thread"})
+		prometheus.MustRegister(execDuration)
+	})
+	return execDuration
+}
+
+// ObserveRetry records one retry attempt for op, so retries caused by
+// contention (e.g. serialization failures under Snapshot/Serializable
+// isolation) can be told apart from genuine failures in the exec_total
+// counter.
+func ObserveRetry(db, op string) {
+	retryTotal.WithLabelValues(db, op).Inc()
+}
+
+// Start launches a Prometheus metrics HTTP server exposing /metrics on addr.
+// It returns immediately and tears the server down once ctx is canceled, so
+// callers can start it alongside the benchmark's own lifetime context.
+func Start(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("metrics server stopped, err %v\n", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+}
+
+// OpNamer is implemented by workloads that can report the name of the
+// operation the next Run call is about to execute (e.g. TPC-C's
+// NewOrder/Payment/Delivery/OrderStatus/StockLevel, or a TPC-H/CH-benCHmark
+// query number), so metrics can be broken down per transaction type instead
+// of per workload. Workloads that don't implement it are labeled "op=run".
+type OpNamer interface {
+	OpName(ctx context.Context, threadID int) string
+}
+
+// Namer is implemented by workloads that can report their own kind (tpcc,
+// tpch, ch), so metrics from different benchmarks sharing one process (or
+// one Grafana dashboard) don't collide. Workloads that don't implement it
+// are labeled "db=workload".
+type Namer interface {
+	Name() string
+}
+
+// WarehouseNamer is implemented by workloads that can report which
+// warehouse the next Run call will touch (TPC-C is partitioned by
+// warehouse), so long runs can be graphed per warehouse in Grafana.
+// Workloads that don't implement it are labeled "warehouse=" (empty).
+type WarehouseNamer interface {
+	WarehouseID(ctx context.Context, threadID int) string
+}
+
+// Wrap runs fn, timing it and recording the result under the given
+// workload/operation/thread/warehouse labels. execute() calls this around
+// every w.Run so all workloads get Prometheus coverage without each one
+// having to instrument itself. w is consulted for RowsReporter so TPC-H's
+// rows-returned can flow into the post-run report alongside wall time.
+func Wrap(ctx context.Context, db, op string, threadID int, warehouse string, w interface{}, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+
+	thread := strconv.Itoa(threadID)
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	execTotal.WithLabelValues(db, op, thread, warehouse, result).Inc()
+	duration().WithLabelValues(db, op, thread).Observe(elapsed.Seconds())
+
+	var rows int64
+	if reporter, ok := w.(RowsReporter); ok {
+		rows = reporter.LastRowsReturned(ctx, threadID)
+	}
+	recordSample(db, op, "run", elapsed, rows, err)
+
+	return err
+}
@@ -0,0 +1,335 @@
+package metrics
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// reportSampleCap bounds how many latency samples a single (db, op, phase)
+// bucket keeps in memory. Runs with millions of transactions are reservoir
+// sampled down to this size rather than retaining every latency forever.
+const reportSampleCap = 100000
+
+// sqlStatePattern pulls a SQLSTATE or vendor error code out of an error
+// message for the report's "errors by code" breakdown, e.g. "SQLSTATE
+// 40001" (Postgres/CockroachDB) or "Error 1213" (MySQL/TiDB).
+var sqlStatePattern = regexp.MustCompile(`SQLSTATE [0-9A-Z]{5}|Error \d{3,5}`)
+
+type sampler struct {
+	mu           sync.Mutex
+	count        int64
+	errCount     int64
+	errorsByCode map[string]int64
+	samples      []time.Duration
+	rowsReturned int64
+	first, last  time.Time
+}
+
+func (s *sampler) record(elapsed time.Duration, rows int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.first.IsZero() {
+		s.first = now
+	}
+	s.last = now
+
+	s.count++
+	s.rowsReturned += rows
+	if err != nil {
+		s.errCount++
+		code := sqlStatePattern.FindString(err.Error())
+		// lib/pq keeps the SQLSTATE out of its error text.
+		var pqErr *pq.Error
+		if len(code) == 0 && errors.As(err, &pqErr) {
+			code = "SQLSTATE " + string(pqErr.Code)
+		}
+		if len(code) == 0 {
+			code = "unknown"
+		}
+		if s.errorsByCode == nil {
+			s.errorsByCode = map[string]int64{}
+		}
+		s.errorsByCode[code]++
+	}
+
+	if len(s.samples) < reportSampleCap {
+		s.samples = append(s.samples, elapsed)
+		return
+	}
+	// reservoir sampling keeps the retained samples representative once
+	// the cap is hit, instead of just dropping everything after it.
+	if i := rand.Int63n(s.count); i < reportSampleCap {
+		s.samples[i] = elapsed
+	}
+}
+
+// RowsReporter is implemented by workloads that can report how many rows
+// their last query returned (TPC-H), so the report can include that
+// alongside wall time instead of just pass/fail.
+type RowsReporter interface {
+	LastRowsReturned(ctx context.Context, threadID int) int64
+}
+
+type reportKey struct {
+	db, op, phase string
+}
+
+var (
+	reportMu sync.Mutex
+	report   = map[reportKey]*sampler{}
+)
+
+func recordSample(db, op, phase string, elapsed time.Duration, rows int64, err error) {
+	key := reportKey{db, op, phase}
+
+	reportMu.Lock()
+	s, ok := report[key]
+	if !ok {
+		s = &sampler{}
+		report[key] = s
+	}
+	reportMu.Unlock()
+
+	s.record(elapsed, rows, err)
+}
+
+// RecordPhase times a prepare/cleanup call for the final report, since
+// those aren't per-transaction Run calls and don't go through Wrap.
+func RecordPhase(db, phase string, elapsed time.Duration, err error) {
+	recordSample(db, "", phase, elapsed, 0, err)
+}
+
+// OpStat is one row of the post-run summary report: a (workload, operation,
+// phase) bucket with its throughput, error breakdown and latency
+// percentiles.
+type OpStat struct {
+	DB           string           `json:"db" toml:"db"`
+	Op           string           `json:"op" toml:"op"`
+	Phase        string           `json:"phase" toml:"phase"`
+	Count        int64            `json:"count" toml:"count"`
+	Errors       int64            `json:"errors" toml:"errors"`
+	RowsReturned int64            `json:"rows_returned" toml:"rows_returned"`
+	ErrorsByCode map[string]int64 `json:"errors_by_code" toml:"errors_by_code"`
+	TPS          float64          `json:"tps" toml:"tps"`
+	MeanLatency  time.Duration    `json:"mean_latency_ns" toml:"mean_latency_ns"`
+	P50          time.Duration    `json:"p50_latency_ns" toml:"p50_latency_ns"`
+	P90          time.Duration    `json:"p90_latency_ns" toml:"p90_latency_ns"`
+	P95          time.Duration    `json:"p95_latency_ns" toml:"p95_latency_ns"`
+	P99          time.Duration    `json:"p99_latency_ns" toml:"p99_latency_ns"`
+	P999         time.Duration    `json:"p999_latency_ns" toml:"p999_latency_ns"`
+	MaxLatency   time.Duration    `json:"max_latency_ns" toml:"max_latency_ns"`
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// Report snapshots every recorded (db, op, phase) bucket into a sorted,
+// stable report, computing percentile latencies from the retained samples.
+func Report() []OpStat {
+	reportMu.Lock()
+	keys := make([]reportKey, 0, len(report))
+	samplers := make([]*sampler, 0, len(report))
+	for k, s := range report {
+		keys = append(keys, k)
+		samplers = append(samplers, s)
+	}
+	reportMu.Unlock()
+
+	stats := make([]OpStat, 0, len(keys))
+	for i, k := range keys {
+		s := samplers[i]
+		s.mu.Lock()
+		sorted := make([]time.Duration, len(s.samples))
+		copy(sorted, s.samples)
+		count, errCount, rows := s.count, s.errCount, s.rowsReturned
+		errorsByCode := make(map[string]int64, len(s.errorsByCode))
+		for code, n := range s.errorsByCode {
+			errorsByCode[code] = n
+		}
+		first, last := s.first, s.last
+		s.mu.Unlock()
+
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		var sum time.Duration
+		for _, d := range sorted {
+			sum += d
+		}
+		var mean time.Duration
+		if len(sorted) > 0 {
+			mean = sum / time.Duration(len(sorted))
+		}
+
+		var tps float64
+		if wall := last.Sub(first); wall > 0 {
+			tps = float64(count) / wall.Seconds()
+		}
+
+		stats = append(stats, OpStat{
+			DB:           k.db,
+			Op:           k.op,
+			Phase:        k.phase,
+			Count:        count,
+			Errors:       errCount,
+			RowsReturned: rows,
+			ErrorsByCode: errorsByCode,
+			TPS:          tps,
+			MeanLatency:  mean,
+			P50:          percentile(sorted, 0.50),
+			P90:          percentile(sorted, 0.90),
+			P95:          percentile(sorted, 0.95),
+			P99:          percentile(sorted, 0.99),
+			P999:         percentile(sorted, 0.999),
+			MaxLatency:   percentile(sorted, 1.0),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].DB != stats[j].DB {
+			return stats[i].DB < stats[j].DB
+		}
+		if stats[i].Phase != stats[j].Phase {
+			return stats[i].Phase < stats[j].Phase
+		}
+		return stats[i].Op < stats[j].Op
+	})
+	return stats
+}
+
+// WriteReport renders Report() to path in the given format ("json", "csv"
+// or "toml"), so CI can diff benchmark results the way sysbench/HammerDB
+// output files are diffed.
+func WriteReport(path, format string) error {
+	stats := Report()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	case "csv":
+		return writeReportCSV(f, stats)
+	case "toml":
+		return writeReportTOML(f, stats)
+	default:
+		return fmt.Errorf("unknown report format: %q", format)
+	}
+}
+
+func writeReportCSV(f *os.File, stats []OpStat) error {
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	header := []string{"db", "op", "phase", "count", "errors", "errors_by_code", "rows_returned", "tps",
+		"mean_latency_ns", "p50_latency_ns", "p90_latency_ns", "p95_latency_ns",
+		"p99_latency_ns", "p999_latency_ns", "max_latency_ns"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+		row := []string{
+			s.DB, s.Op, s.Phase,
+			strconv.FormatInt(s.Count, 10),
+			strconv.FormatInt(s.Errors, 10),
+			formatErrorsByCode(s.ErrorsByCode),
+			strconv.FormatInt(s.RowsReturned, 10),
+			strconv.FormatFloat(s.TPS, 'f', 2, 64),
+			strconv.FormatInt(s.MeanLatency.Nanoseconds(), 10),
+			strconv.FormatInt(s.P50.Nanoseconds(), 10),
+			strconv.FormatInt(s.P90.Nanoseconds(), 10),
+			strconv.FormatInt(s.P95.Nanoseconds(), 10),
+			strconv.FormatInt(s.P99.Nanoseconds(), 10),
+			strconv.FormatInt(s.P999.Nanoseconds(), 10),
+			strconv.FormatInt(s.MaxLatency.Nanoseconds(), 10),
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatErrorsByCode flattens an error-code breakdown into a single CSV
+// cell ("code:count;code:count", sorted by code for deterministic output),
+// since CSV has no native nested-map column the way JSON/TOML do.
+func formatErrorsByCode(errorsByCode map[string]int64) string {
+	codes := make([]string, 0, len(errorsByCode))
+	for code := range errorsByCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		parts = append(parts, fmt.Sprintf("%s:%d", code, errorsByCode[code]))
+	}
+	return strings.Join(parts, ";")
+}
+
+// writeReportTOML hand-renders an array of tables; the report's shape
+// (a flat list of uniform records) doesn't need a general-purpose TOML
+// encoder dependency.
+func writeReportTOML(f *os.File, stats []OpStat) error {
+	for _, s := range stats {
+		if _, err := fmt.Fprintf(f, "[[op]]\ndb = %q\nop = %q\nphase = %q\ncount = %d\nerrors = %d\nrows_returned = %d\ntps = %f\n",
+			s.DB, s.Op, s.Phase, s.Count, s.Errors, s.RowsReturned, s.TPS); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(f,
+			"mean_latency_ns = %d\np50_latency_ns = %d\np90_latency_ns = %d\np95_latency_ns = %d\np99_latency_ns = %d\np999_latency_ns = %d\nmax_latency_ns = %d\n",
+			s.MeanLatency.Nanoseconds(), s.P50.Nanoseconds(), s.P90.Nanoseconds(), s.P95.Nanoseconds(),
+			s.P99.Nanoseconds(), s.P999.Nanoseconds(), s.MaxLatency.Nanoseconds()); err != nil {
+			return err
+		}
+		if len(s.ErrorsByCode) > 0 {
+			codes := make([]string, 0, len(s.ErrorsByCode))
+			for code := range s.ErrorsByCode {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+
+			if _, err := fmt.Fprint(f, "[op.errors_by_code]\n"); err != nil {
+				return err
+			}
+			for _, code := range codes {
+				if _, err := fmt.Fprintf(f, "%q = %d\n", code, s.ErrorsByCode[code]); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := fmt.Fprintln(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package workload
+
+import "context"
+
+// OLAPRunner is implemented by workloads that mix a TPC-C-style OLTP
+// terminal stream with a smaller pool of analytical query streams, i.e.
+// CH-benCHmark. Workloads that don't implement it only ever get their Run
+// method called, on --threads goroutines, as before.
+type OLAPRunner interface {
+	Workloader
+
+	// RunOLAP executes one analytical query on the OLAP stream identified
+	// by threadID (0..acThreads-1, a separate index space from the OLTP
+	// Run threads). Implementations cycle through the benchmark's queries
+	// in order across successive calls for a given threadID.
+	RunOLAP(ctx context.Context, threadID int) error
+}
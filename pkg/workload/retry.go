@@ -0,0 +1,96 @@
+package workload
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pingcap/go-tpc/pkg/metrics"
+)
+
+// retryBackoff is the base delay for the first retry; subsequent retries
+// back off exponentially, capped at retryMaxBackoff, to avoid hammering a
+// database that is already contended.
+const (
+	retryBackoff    = 5 * time.Millisecond
+	retryMaxBackoff = 500 * time.Millisecond
+)
+
+// IsRetryable reports whether err is a transient, contention-related error
+// that's worth retrying the whole logical transaction for, rather than
+// failing the run or (with --ignore-error) silently dropping it from the
+// tpmC count. driver is the --driver value (mysql, postgres, ...) since the
+// same condition surfaces under different codes per wire protocol.
+func IsRetryable(driver string, err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+
+	// TiDB reports write conflicts over the MySQL wire protocol, so this
+	// has to be checked regardless of which case below also matches.
+	if strings.Contains(msg, "Write conflict") {
+		return true
+	}
+
+	switch driver {
+	case "mysql":
+		// 1213: deadlock found when trying to get lock
+		// 1205: lock wait timeout exceeded
+		return strings.Contains(msg, "Error 1213") || strings.Contains(msg, "Error 1205")
+	case "postgres", "cockroachdb":
+		// 40001: serialization_failure, 40P01: deadlock_detected
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			return pqErr.Code == "40001" || pqErr.Code == "40P01"
+		}
+		return strings.Contains(msg, "SQLSTATE 40001") || strings.Contains(msg, "SQLSTATE 40P01")
+	}
+
+	return false
+}
+
+// RunInNewTxn retries run up to maxRetries times, backing off
+// exponentially between attempts, as long as the error IsRetryable for
+// driver. Retries are reported to Prometheus separately from final errors
+// so users can distinguish contention from real failures. db and op label
+// the retry metric; they're the same labels the caller records the
+// eventual result under.
+func RunInNewTxn(ctx context.Context, driver, db, op string, maxRetries int, run func() error) error {
+	var err error
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		err = run()
+		if err == nil || !IsRetryable(driver, err) {
+			return err
+		}
+		if attempt == maxRetries {
+			break
+		}
+
+		metrics.ObserveRetry(db, op)
+
+		// cap the shift itself, not just the result: retryBackoff<<uint(attempt)
+		// overflows time.Duration well before attempt reaches maxRetries for
+		// large --max-retries values, which would wrap around to a tiny
+		// (effectively zero) backoff and busy-loop retries instead of
+		// capping at retryMaxBackoff.
+		shift := attempt
+		if shift > 16 {
+			shift = 16
+		}
+		backoff := retryBackoff << uint(shift)
+		if backoff > retryMaxBackoff {
+			backoff = retryMaxBackoff
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return err
+}
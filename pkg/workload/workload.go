@@ -0,0 +1,22 @@
+package workload
+
+import "context"
+
+// Workloader is the interface every benchmark (TPC-C, TPC-H, CH-benCHmark,
+// raw SQL) implements so cmd/go-tpc's execute/executeWorkload can drive any
+// of them the same way: per-thread setup/teardown around repeated Run calls,
+// plus the prepare/cleanup phases driven before and after a run.
+type Workloader interface {
+	// InitThread returns a context carrying any per-thread state (e.g. a
+	// dedicated connection) the workload needs for threadID's later
+	// Prepare/Cleanup/Run calls.
+	InitThread(ctx context.Context, threadID int) context.Context
+	// CleanupThread releases whatever InitThread set up for threadID.
+	CleanupThread(ctx context.Context, threadID int)
+	// Prepare populates the data a run needs, e.g. loading the schema.
+	Prepare(ctx context.Context, threadID int) error
+	// Cleanup removes whatever Prepare created.
+	Cleanup(ctx context.Context, threadID int) error
+	// Run executes one logical transaction/query on threadID's connection.
+	Run(ctx context.Context, threadID int) error
+}